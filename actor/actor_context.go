@@ -0,0 +1,156 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"fmt"
+	"time"
+)
+
+// Actor is the behavior a user implements. Receive is invoked once per message via
+// actorContext.invokeUserMessage, which is what actually records
+// protoactor_actor_message_receive_duration_seconds around the call.
+type Actor interface {
+	Receive(ctx Context)
+}
+
+// Context is the view of its own runtime an actor gets inside Receive: enough to look up the
+// system, itself, its parent/children and the message in flight. It's also what Metrics and
+// Tracing tag their instruments and spans from (see Metrics.CommonLabels), and what Send/Request
+// dispatch through the system's SenderMiddleware chain.
+type Context interface {
+	ActorSystem() *ActorSystem
+	Actor() Actor
+	Self() *PID
+	Parent() *PID
+	Children() []*PID
+	Message() interface{}
+	Stash()
+	Send(target *PID, message interface{})
+	Request(target *PID, message interface{})
+}
+
+// actorContext is the concrete Context: one per spawned PID, created by Props.Spawn and torn
+// down by stop.
+type actorContext struct {
+	system *ActorSystem
+	props  *Props
+	self   *PID
+	parent *PID
+	actor  Actor
+
+	children []*PID
+	stash    []interface{}
+	message  interface{}
+
+	// headers carries the sender's TracingHeaders for the message currently being processed, set
+	// by invokeUserMessage right before the ReceiverMiddleware chain runs so
+	// WithTracingReceiverMiddleware can continue the sender's trace instead of starting a new root
+	// span. It's nil whenever Tracing is disabled or the send path didn't inject any.
+	headers TracingHeaders
+
+	deregisterChildren func()
+	deregisterStash    func()
+}
+
+func newActorContext(system *ActorSystem, props *Props, self, parent *PID) *actorContext {
+	return &actorContext{system: system, props: props, self: self, parent: parent}
+}
+
+func (ctx *actorContext) ActorSystem() *ActorSystem { return ctx.system }
+func (ctx *actorContext) Actor() Actor              { return ctx.actor }
+func (ctx *actorContext) Self() *PID                { return ctx.self }
+func (ctx *actorContext) Parent() *PID              { return ctx.parent }
+func (ctx *actorContext) Children() []*PID          { return ctx.children }
+func (ctx *actorContext) Message() interface{}      { return ctx.message }
+
+func (ctx *actorContext) addChild(pid *PID) {
+	ctx.children = append(ctx.children, pid)
+}
+
+// Send delivers message to target, synchronously invoking its Receive the same way the mailbox
+// would. It runs through the system's SenderMiddleware chain, whose only middleware is
+// WithTracingSenderMiddleware, which brackets the delivery with a "send <MessageType>" span when Tracing
+// is enabled.
+func (ctx *actorContext) Send(target *PID, message interface{}) {
+	ctx.system.wrapSender(baseSend)(ctx, target, message, nil)
+}
+
+// Request is Send with ask semantics in mind; this minimal dispatch path has no future/response
+// plumbing yet, so it is currently just Send.
+func (ctx *actorContext) Request(target *PID, message interface{}) {
+	ctx.Send(target, message)
+}
+
+// baseSend is the innermost SenderFunc: look the target up in the ProcessRegistry and hand it
+// the message directly, mirroring how the real mailbox would dispatch it. headers carries
+// whatever WithTracingSenderMiddleware injected (nil when Tracing is disabled), straight through
+// to invokeUserMessage so the receive side can continue the same trace.
+func baseSend(ctx Context, target *PID, message interface{}, headers TracingHeaders) {
+	targetCtx := ctx.ActorSystem().ProcessRegistry.get(target)
+	if targetCtx == nil {
+		return
+	}
+	targetCtx.invokeUserMessage(message, headers)
+}
+
+// Stash defers the message currently being processed. The first call registers this actor's
+// protoactor_actor_stash_size gauge, so an actor that never stashes registers none.
+func (ctx *actorContext) Stash() {
+	if ctx.deregisterStash == nil {
+		ctx.deregisterStash = registerStashSizeProvider(func() (int, bool) {
+			return len(ctx.stash), false
+		}, ctx.system.Metrics().CommonLabels(ctx))
+	}
+	ctx.stash = append(ctx.stash, ctx.message)
+}
+
+// start creates the actor's behavior and registers its protoactor_actor_children_count gauge.
+func (ctx *actorContext) start() {
+	ctx.actor = ctx.props.producer()
+
+	labels := ctx.system.Metrics().CommonLabels(ctx)
+	ctx.deregisterChildren = registerChildrenCountProvider(func() (int, bool) {
+		return len(ctx.children), false
+	}, labels)
+
+	ctx.system.ProcessRegistry.add(ctx)
+}
+
+// stop tears the actor's gauges down so they don't outlive the PID, and removes it from the
+// ProcessRegistry that protoactor_actor_system_actor_count is derived from.
+func (ctx *actorContext) stop() {
+	if ctx.deregisterChildren != nil {
+		ctx.deregisterChildren()
+	}
+	if ctx.deregisterStash != nil {
+		ctx.deregisterStash()
+	}
+	ctx.system.ProcessRegistry.remove(ctx.self)
+}
+
+// invokeUserMessage is the context dispatch path: it runs Actor.Receive for message through the
+// system's ReceiverMiddleware chain, recording protoactor_actor_message_receive_duration_seconds
+// around the call, and handing any panic to the supervisor. headers is whatever the sender's
+// Tracing middleware injected (nil if Tracing was disabled on send), stashed on ctx so
+// WithTracingReceiverMiddleware can link its span to the sender's instead of starting a new trace.
+func (ctx *actorContext) invokeUserMessage(message interface{}, headers TracingHeaders) {
+	start := time.Now()
+	ctx.message = message
+	ctx.headers = headers
+	messageType := fmt.Sprintf("%T", message)
+
+	defer func() {
+		ctx.system.Metrics().RecordReceiveDuration(ctx, messageType, time.Since(start))
+		if reason := recover(); reason != nil {
+			ctx.handleFailure(reason)
+		}
+	}()
+
+	ctx.system.wrapReceiver(baseReceive)(ctx)
+}
+
+// baseReceive is the innermost ReceiverFunc: just run the actor's own behavior.
+func baseReceive(ctx Context) {
+	ctx.(*actorContext).actor.Receive(ctx)
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"testing"
+
+	"github.com/asynkron/protoactor-go/extensions"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// fakeInstrumentRecorder lets tests observe RecordRestart/RecordReceiveDuration without an OTel
+// or Prometheus backend behind them.
+type fakeInstrumentRecorder struct {
+	restarts  int
+	durations []float64
+}
+
+func (f *fakeInstrumentRecorder) RecordRestart(labels []attribute.KeyValue) {
+	f.restarts++
+}
+
+func (f *fakeInstrumentRecorder) RecordReceiveDuration(labels []attribute.KeyValue, seconds float64) {
+	f.durations = append(f.durations, seconds)
+}
+
+func newTestSystem(recorder instrumentRecorder) *ActorSystem {
+	system := &ActorSystem{id: nextActorSystemID(), address: "test", extensions: extensions.NewExtensions()}
+	system.ProcessRegistry = newProcessRegistry(system)
+	system.extensions.Set(&Metrics{enabled: true, recorder: recorder})
+	return system
+}
+
+type countingActor struct {
+	receives int
+	panicOn  int
+}
+
+func (a *countingActor) Receive(ctx Context) {
+	a.receives++
+	if a.receives == a.panicOn {
+		panic("boom")
+	}
+}
+
+func TestInvokeUserMessageRecordsReceiveDuration(t *testing.T) {
+	fake := &fakeInstrumentRecorder{}
+	system := newTestSystem(fake)
+	defer system.Shutdown()
+
+	pid := PropsFromProducer(func() Actor { return &countingActor{} }).Spawn(system, nil)
+	ctx := system.ProcessRegistry.get(pid)
+
+	ctx.invokeUserMessage("hello", nil)
+
+	if len(fake.durations) != 1 {
+		t.Fatalf("expected one receive-duration observation, got %d", len(fake.durations))
+	}
+}
+
+func TestInvokeUserMessageRestartsOnPanicAndRecordsRestart(t *testing.T) {
+	fake := &fakeInstrumentRecorder{}
+	system := newTestSystem(fake)
+	defer system.Shutdown()
+
+	pid := PropsFromProducer(func() Actor { return &countingActor{panicOn: 1} }).Spawn(system, nil)
+	ctx := system.ProcessRegistry.get(pid)
+	original := ctx.Actor()
+
+	ctx.invokeUserMessage("boom", nil)
+
+	if fake.restarts != 1 {
+		t.Fatalf("expected exactly one restart to be recorded, got %d", fake.restarts)
+	}
+	if len(fake.durations) != 1 {
+		t.Fatalf("expected the panicking receive to still record its duration, got %d", len(fake.durations))
+	}
+	if ctx.Actor() == original {
+		t.Fatalf("expected restart to replace the actor's behavior via Props.producer")
+	}
+}
+
+func TestContextSendDeliversToTarget(t *testing.T) {
+	system := newTestSystem(nil)
+	defer system.Shutdown()
+
+	received := make(chan interface{}, 1)
+	targetPID := PropsFromProducer(func() Actor {
+		return receiveFunc(func(ctx Context) { received <- ctx.Message() })
+	}).Spawn(system, nil)
+
+	senderPID := PropsFromProducer(func() Actor { return receiveFunc(func(Context) {}) }).Spawn(system, nil)
+	sender := system.ProcessRegistry.get(senderPID)
+
+	sender.Send(targetPID, "hi")
+
+	select {
+	case msg := <-received:
+		if msg != "hi" {
+			t.Fatalf("expected target to receive %q, got %v", "hi", msg)
+		}
+	default:
+		t.Fatalf("expected Send to synchronously deliver the message to target")
+	}
+}
+
+// receiveFunc adapts a plain func(Context) into an Actor, for tests that don't need a named type.
+type receiveFunc func(ctx Context)
+
+func (f receiveFunc) Receive(ctx Context) { f(ctx) }
@@ -0,0 +1,109 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/asynkron/protoactor-go/extensions"
+)
+
+// ActorSystem is the root of a set of locally-spawned actors. It owns the extension
+// registry Metrics and Tracing hang off of, and the ProcessRegistry that backs
+// protoactor_actor_system_actor_count.
+type ActorSystem struct {
+	id              string
+	address         string
+	extensions      *extensions.Extensions
+	ProcessRegistry *ProcessRegistry
+}
+
+var actorSystemSequence uint64
+
+// nextActorSystemID returns a process-unique id, used as the actorCountProviders registry key
+// so two ActorSystems sharing the same address (e.g. the "nonhost" default) don't clobber each
+// other's protoactor_actor_system_actor_count registration.
+func nextActorSystemID() string {
+	return fmt.Sprintf("actorsystem-%d", atomic.AddUint64(&actorSystemSequence, 1))
+}
+
+// NewActorSystem builds an ActorSystem from config, enabling whichever extensions config
+// requested.
+func NewActorSystem(config *ActorSystemConfig) *ActorSystem {
+	if config == nil {
+		config = NewActorSystemConfig()
+	}
+
+	system := &ActorSystem{
+		id:         nextActorSystemID(),
+		address:    "nonhost",
+		extensions: extensions.NewExtensions(),
+	}
+	system.ProcessRegistry = newProcessRegistry(system)
+
+	if config.metricsProvider != nil {
+		system.extensions.Set(NewMetrics(config.metricsProvider))
+	}
+	if config.tracingProvider != nil {
+		system.extensions.Set(NewTracing(config.tracingProvider))
+	}
+
+	RegisterActorCountProvider(system.id, system.address, func() int64 {
+		return int64(system.ProcessRegistry.ActorCount())
+	})
+
+	return system
+}
+
+func (system *ActorSystem) Address() string {
+	return system.address
+}
+
+// Shutdown releases the resources NewActorSystem registered outside the ActorSystem itself,
+// currently just its protoactor_actor_system_actor_count provider. Callers that create an
+// ActorSystem for the lifetime of a test or a short-lived process should call this once done,
+// so the package-global actorCountProviders map doesn't accumulate dead entries.
+func (system *ActorSystem) Shutdown() {
+	UnregisterActorCountProvider(system.id)
+}
+
+// Metrics returns the system's Metrics extension, or a disabled no-op one if config didn't
+// enable it, so callers never need a nil check.
+func (system *ActorSystem) Metrics() *Metrics {
+	if m, ok := system.extensions.Get(extensionId).(*Metrics); ok {
+		return m
+	}
+	return &Metrics{}
+}
+
+// Tracing returns the system's Tracing extension, or a disabled no-op one if config didn't
+// enable it via WithTracing, so callers (e.g. the tracing middleware below) never need a nil
+// check.
+func (system *ActorSystem) Tracing() *Tracing {
+	if t, ok := system.extensions.Get(tracingExtensionId).(*Tracing); ok {
+		return t
+	}
+	return NewTracing(nil)
+}
+
+// wrapReceiver builds the ReceiverFunc chain invokeUserMessage runs: base wrapped with
+// WithTracingReceiverMiddleware when Tracing is enabled. With a disabled or absent Tracing
+// extension the chain is base alone: no middleware is added and no span is started.
+func (system *ActorSystem) wrapReceiver(base ReceiverFunc) ReceiverFunc {
+	receive := base
+	if tracing := system.Tracing(); tracing.Enabled() {
+		receive = WithTracingReceiverMiddleware(tracing)(receive)
+	}
+	return receive
+}
+
+// wrapSender builds the SenderFunc chain Context.Send runs: base wrapped with
+// WithTracingSenderMiddleware when Tracing is enabled, mirroring wrapReceiver.
+func (system *ActorSystem) wrapSender(base SenderFunc) SenderFunc {
+	send := base
+	if tracing := system.Tracing(); tracing.Enabled() {
+		send = WithTracingSenderMiddleware(tracing)(send)
+	}
+	return send
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ActorSystemConfig carries the options NewActorSystem uses to wire up optional extensions
+// such as Metrics and Tracing, built via the ConfigOption functions below.
+type ActorSystemConfig struct {
+	metricsProvider metric.MeterProvider
+	tracingProvider trace.TracerProvider
+}
+
+// ConfigOption mutates an ActorSystemConfig; pass one or more to NewActorSystemConfig.
+type ConfigOption func(*ActorSystemConfig)
+
+func NewActorSystemConfig(options ...ConfigOption) *ActorSystemConfig {
+	config := &ActorSystemConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
+
+// WithMetricsProvider enables the Metrics extension on the ActorSystem built from this
+// config, backed by provider.
+func WithMetricsProvider(provider metric.MeterProvider) ConfigOption {
+	return func(config *ActorSystemConfig) {
+		config.metricsProvider = provider
+	}
+}
+
+// WithTracing enables the Tracing extension on the ActorSystem built from this config, backed
+// by provider. Without it, ActorSystem.Tracing returns a disabled, no-op Tracing so the
+// ActorSystem.wrapSender/wrapReceiver leave the tracing middleware out of the chain when
+// tracing isn't configured.
+func WithTracing(provider trace.TracerProvider) ConfigOption {
+	return func(config *ActorSystemConfig) {
+		config.tracingProvider = provider
+	}
+}
@@ -3,7 +3,6 @@
 package actor
 
 import (
-	"container/list"
 	"context"
 	"fmt"
 	"sort"
@@ -25,8 +24,32 @@ import (
 var extensionId = extensions.NextExtensionID()
 
 type Metrics struct {
-	metrics *metrics.ProtoMetrics
-	enabled bool
+	metrics  *metrics.ProtoMetrics
+	recorder instrumentRecorder
+	registry interface{} // *prometheus.Registry when built with NewPrometheusMetrics, nil otherwise
+	enabled  bool
+}
+
+// instrumentRecorder abstracts the counter/histogram instruments that are recorded
+// directly on the hot path (as opposed to the async gauges, which are scraped from the
+// provider registries below). This lets RecordRestart/RecordReceiveDuration work
+// identically whether Metrics was built with NewMetrics (OpenTelemetry) or
+// NewPrometheusMetrics (native Prometheus).
+type instrumentRecorder interface {
+	RecordRestart(labels []attribute.KeyValue)
+	RecordReceiveDuration(labels []attribute.KeyValue, seconds float64)
+}
+
+type otelInstrumentRecorder struct {
+	instruments *metrics.Instruments
+}
+
+func (r *otelInstrumentRecorder) RecordRestart(labels []attribute.KeyValue) {
+	r.instruments.ActorRestartCount.Add(context.Background(), 1, labels...)
+}
+
+func (r *otelInstrumentRecorder) RecordReceiveDuration(labels []attribute.KeyValue, seconds float64) {
+	r.instruments.ActorMessageReceiveDuration.Record(context.Background(), seconds, labels...)
 }
 
 var _ extensions.Extension = &Metrics{}
@@ -51,36 +74,54 @@ func NewMetrics(provider metric.MeterProvider) *Metrics {
 
 	if instruments := m.metrics.Get(metrics.InternalActorMetrics); instruments != nil {
 		m.PrepareMailboxLengthGauge()
+		m.PrepareStashSizeGauge()
+		m.PrepareChildrenGauge()
+		m.PrepareActorCountGauge()
+		m.PrepareRestartCounter()
+		m.PrepareReceiveDurationHistogram()
+		m.recorder = &otelInstrumentRecorder{instruments: instruments}
+
 		meter := global.Meter(metrics.LibName)
 		if err := meter.RegisterCallback([]instrument.Asynchronous{instruments.ActorMailboxLength}, func(goCtx context.Context) {
 			var start = time.Now()
-			i := 0
-			deleted := 0
-			for _, mbsProvider := range mailboxSizeProviders {
-				var count int64 = 0
-				mbsProvider.Lock()
-				for e := mbsProvider.invokers.Front(); e != nil; {
-					if c, dead := e.Value.(mailboxSizeInvoker)(); dead {
-						deadElem := e
-						e = e.Next() // needs to be before Remove
-						mbsProvider.invokers.Remove(deadElem)
-						deleted++
-					} else {
-						count += int64(c)
-						e = e.Next()
-					}
-					i++
-				}
-				mbsProvider.Unlock()
-				instruments.ActorMailboxLength.Observe(goCtx, count, mbsProvider.labels...)
+			values := snapshotMailboxSizes()
+			for _, lv := range values {
+				instruments.ActorMailboxLength.Observe(goCtx, lv.value, lv.labels...)
 			}
-			fmt.Printf("elapsed: %v, providers: %v, iterated: %v, deleted: %v\n",
-				time.Since(start), len(mailboxSizeProviders), i, deleted)
-
+			plog.Debug("mailbox length scrape completed",
+				log.Duration("elapsed", time.Since(start)),
+				log.Int("observed", len(values)))
 		}); err != nil {
 			err = fmt.Errorf("failed to instrument Actor Mailbox, %w", err)
 			plog.Error(err.Error(), log.Error(err))
 		}
+
+		if err := meter.RegisterCallback([]instrument.Asynchronous{instruments.ActorStashSize}, func(goCtx context.Context) {
+			for _, lv := range snapshotStashSizes() {
+				instruments.ActorStashSize.Observe(goCtx, lv.value, lv.labels...)
+			}
+		}); err != nil {
+			err = fmt.Errorf("failed to instrument Actor Stash, %w", err)
+			plog.Error(err.Error(), log.Error(err))
+		}
+
+		if err := meter.RegisterCallback([]instrument.Asynchronous{instruments.ActorChildrenCount}, func(goCtx context.Context) {
+			for _, lv := range snapshotChildrenCounts() {
+				instruments.ActorChildrenCount.Observe(goCtx, lv.value, lv.labels...)
+			}
+		}); err != nil {
+			err = fmt.Errorf("failed to instrument Actor Children, %w", err)
+			plog.Error(err.Error(), log.Error(err))
+		}
+
+		if err := meter.RegisterCallback([]instrument.Asynchronous{instruments.ActorSystemActorCount}, func(goCtx context.Context) {
+			for _, lv := range snapshotActorCounts() {
+				instruments.ActorSystemActorCount.Observe(goCtx, lv.value, lv.labels...)
+			}
+		}); err != nil {
+			err = fmt.Errorf("failed to instrument System Actor Count, %w", err)
+			plog.Error(err.Error(), log.Error(err))
+		}
 	}
 
 	return m
@@ -99,45 +140,233 @@ func (m *Metrics) PrepareMailboxLengthGauge() {
 	m.metrics.Instruments().SetActorMailboxLengthGauge(gauge)
 }
 
+// PrepareStashSizeGauge registers the async gauge backing protoactor_actor_stash_size.
+// Actual observations come from stashSizeRegistry, populated by actorContext.Stash the
+// first time an actor stashes a message and deregistered by actorContext.stop.
+func (m *Metrics) PrepareStashSizeGauge() {
+	meter := global.Meter(metrics.LibName)
+	gauge, err := meter.AsyncInt64().Gauge("protoactor_actor_stash_size",
+		instrument.WithDescription("Actor's Stash Size"),
+		instrument.WithUnit(unit.Dimensionless))
+
+	if err != nil {
+		err = fmt.Errorf("failed to create ActorStashSize instrument, %w", err)
+		plog.Error(err.Error(), log.Error(err))
+	}
+	m.metrics.Instruments().SetActorStashSizeGauge(gauge)
+}
+
+// PrepareChildrenGauge registers the async gauge backing protoactor_actor_children_count.
+// Observations come from childrenCountRegistry, registered by actorContext.start and
+// deregistered by actorContext.stop, so the count tracks Context.Children() without walking
+// the process registry on every scrape.
+func (m *Metrics) PrepareChildrenGauge() {
+	meter := global.Meter(metrics.LibName)
+	gauge, err := meter.AsyncInt64().Gauge("protoactor_actor_children_count",
+		instrument.WithDescription("Actor's Children Count"),
+		instrument.WithUnit(unit.Dimensionless))
+
+	if err != nil {
+		err = fmt.Errorf("failed to create ActorChildrenCount instrument, %w", err)
+		plog.Error(err.Error(), log.Error(err))
+	}
+	m.metrics.Instruments().SetActorChildrenCountGauge(gauge)
+}
+
+// PrepareActorCountGauge registers the system-wide async gauge backing
+// protoactor_actor_system_actor_count. Each ActorSystem registers its own provider via
+// RegisterActorCountProvider, keyed by its unique id, when its ProcessRegistry is created.
+func (m *Metrics) PrepareActorCountGauge() {
+	meter := global.Meter(metrics.LibName)
+	gauge, err := meter.AsyncInt64().Gauge("protoactor_actor_system_actor_count",
+		instrument.WithDescription("Number of actors currently registered with the ActorSystem"),
+		instrument.WithUnit(unit.Dimensionless))
+
+	if err != nil {
+		err = fmt.Errorf("failed to create ActorSystemActorCount instrument, %w", err)
+		plog.Error(err.Error(), log.Error(err))
+	}
+	m.metrics.Instruments().SetActorSystemActorCountGauge(gauge)
+}
+
+// PrepareRestartCounter registers the counter backing protoactor_actor_restarts_total,
+// incremented by RecordRestart from the supervision path each time a PID is restarted.
+func (m *Metrics) PrepareRestartCounter() {
+	meter := global.Meter(metrics.LibName)
+	counter, err := meter.SyncInt64().Counter("protoactor_actor_restarts_total",
+		instrument.WithDescription("Number of actor restarts"),
+		instrument.WithUnit(unit.Dimensionless))
+
+	if err != nil {
+		err = fmt.Errorf("failed to create ActorRestartCount instrument, %w", err)
+		plog.Error(err.Error(), log.Error(err))
+	}
+	m.metrics.Instruments().SetActorRestartCountCounter(counter)
+}
+
+// PrepareReceiveDurationHistogram registers the histogram backing
+// protoactor_actor_message_receive_duration_seconds, recorded by RecordReceiveDuration
+// around every Actor.Receive invocation in the context dispatch path.
+func (m *Metrics) PrepareReceiveDurationHistogram() {
+	meter := global.Meter(metrics.LibName)
+	histogram, err := meter.SyncFloat64().Histogram("protoactor_actor_message_receive_duration_seconds",
+		instrument.WithDescription("Actor's Message Receive Duration"),
+		instrument.WithUnit(unit.Unit("s")))
+
+	if err != nil {
+		err = fmt.Errorf("failed to create ActorMessageReceiveDuration instrument, %w", err)
+		plog.Error(err.Error(), log.Error(err))
+	}
+	m.metrics.Instruments().SetActorMessageReceiveDurationHistogram(histogram)
+}
+
 func (m *Metrics) CommonLabels(ctx Context) []attribute.KeyValue {
 	labels := []attribute.KeyValue{
 		attribute.String("address", ctx.ActorSystem().Address()),
-		attribute.String("actortype", strings.Replace(fmt.Sprintf("%T", ctx.Actor()), "*", "", 1)),
+		attribute.String("actortype", actorTypeName(ctx)),
 	}
 
 	return labels
 }
 
-type mailboxSizeInvoker func() (mailboxSize int, dead bool)
+// actorTypeName returns ctx.Actor()'s type name with the leading pointer "*" stripped (e.g.
+// "MyActor" rather than "*MyActor"), the label value both Metrics.CommonLabels and
+// Tracing.spanAttributes tag instruments and spans with.
+func actorTypeName(ctx Context) string {
+	return strings.Replace(fmt.Sprintf("%T", ctx.Actor()), "*", "", 1)
+}
+
+// RecordRestart increments protoactor_actor_restarts_total for the actor behind ctx.
+// Called from the supervisor directive handler right before the PID is restarted.
+func (m *Metrics) RecordRestart(ctx Context) {
+	if m.recorder != nil {
+		m.recorder.RecordRestart(m.CommonLabels(ctx))
+	}
+}
+
+// RecordReceiveDuration records how long a single Actor.Receive invocation took, tagged
+// with the actor and message types. messageType is typically derived from fmt.Sprintf("%T", msg).
+func (m *Metrics) RecordReceiveDuration(ctx Context, messageType string, d time.Duration) {
+	if m.recorder != nil {
+		labels := append(m.CommonLabels(ctx), attribute.String("messagetype", messageType))
+		m.recorder.RecordReceiveDuration(labels, d.Seconds())
+	}
+}
+
+// labeledInt64 is a single gauge observation: the attribute set it's tagged with, and its
+// current value. Both the OTel async-gauge callbacks and the Prometheus Collect path pull
+// observations through the same snapshot* functions below, so the two backends can never
+// drift on which actors are counted or how dead invokers are swept.
+type labeledInt64 struct {
+	labels []attribute.KeyValue
+	value  int64
+}
+
+func snapshotMailboxSizes() []labeledInt64 {
+	return mailboxSizeRegistry.snapshot()
+}
 
-var mailboxSizeProviders = make(map[string]*mailboxSizeProvider)
+func snapshotStashSizes() []labeledInt64 {
+	return stashSizeRegistry.snapshot()
+}
 
-type mailboxSizeProvider struct {
-	labels   []attribute.KeyValue
-	invokers *list.List
-	sync.RWMutex
+func snapshotChildrenCounts() []labeledInt64 {
+	return childrenCountRegistry.snapshot()
 }
 
-func registerMailboxSizeProvider(invoker mailboxSizeInvoker, labels []attribute.KeyValue) {
-	labelsAsString := labelsToString(labels)
-	var provider *mailboxSizeProvider
-	if p, ok := mailboxSizeProviders[labelsAsString]; ok {
-		provider = p
-	} else {
-		provider = &mailboxSizeProvider{labels: labels, invokers: list.New()}
-		mailboxSizeProviders[labelsAsString] = provider
+func snapshotActorCounts() []labeledInt64 {
+	actorCountProvidersMu.RLock()
+	defer actorCountProvidersMu.RUnlock()
+	values := make([]labeledInt64, 0, len(actorCountProviders))
+	for _, provider := range actorCountProviders {
+		values = append(values, labeledInt64{
+			labels: []attribute.KeyValue{attribute.String("address", provider.address)},
+			value:  provider.count(),
+		})
 	}
-	provider.Lock()
-	provider.invokers.PushBack(invoker)
-	provider.Unlock()
+	return values
 }
 
+// mailboxSizeInvoker reports the current length of a single actor's mailbox, and whether that
+// actor is gone and the invoker can be dropped. Callers only ever go through
+// registerMailboxSizeProvider - they don't need to know anything about sizeProviderRegistry.
+type mailboxSizeInvoker func() (mailboxSize int, dead bool)
+
+var mailboxSizeRegistry = newSizeProviderRegistry()
+
+// registerMailboxSizeProvider registers invoker under labels and returns a Deregister closure.
+// The caller must call it once the actor stops so the registry doesn't keep scraping a PID that
+// no longer exists. actorContext.stop does not call one: actorContext registers no mailbox size
+// provider of its own.
+func registerMailboxSizeProvider(invoker mailboxSizeInvoker, labels []attribute.KeyValue) func() {
+	return mailboxSizeRegistry.register(sizeInvoker(invoker), labels)
+}
+
+// stashSizeInvoker mirrors mailboxSizeInvoker: it reports the current depth of a single
+// actor's stash, and whether that actor is gone and the invoker can be dropped.
+type stashSizeInvoker func() (stashSize int, dead bool)
+
+var stashSizeRegistry = newSizeProviderRegistry()
+
+// registerStashSizeProvider is called by actorContext.Stash when an actor first stashes a
+// message, mirroring registerMailboxSizeProvider including the Deregister contract.
+func registerStashSizeProvider(invoker stashSizeInvoker, labels []attribute.KeyValue) func() {
+	return stashSizeRegistry.register(sizeInvoker(invoker), labels)
+}
+
+// childrenCountInvoker mirrors mailboxSizeInvoker: it reports len(Context.Children()) for
+// a single actor, and whether that actor is gone and the invoker can be dropped.
+type childrenCountInvoker func() (childrenCount int, dead bool)
+
+var childrenCountRegistry = newSizeProviderRegistry()
+
+// registerChildrenCountProvider is called from actorContext.start once an actor has been
+// created, mirroring registerMailboxSizeProvider including the Deregister contract.
+func registerChildrenCountProvider(invoker childrenCountInvoker, labels []attribute.KeyValue) func() {
+	return childrenCountRegistry.register(sizeInvoker(invoker), labels)
+}
+
+type actorCountProvider struct {
+	address string
+	count   func() int64
+}
+
+var (
+	actorCountProvidersMu sync.RWMutex
+	actorCountProviders   = make(map[string]actorCountProvider)
+)
+
+// RegisterActorCountProvider wires an ActorSystem's ProcessRegistry into
+// protoactor_actor_system_actor_count, tagged with the system's address but keyed by key, a
+// handle unique to that ActorSystem instance (see ActorSystem.id). Keying by address instead
+// would collide whenever two local ActorSystems share one address (e.g. the "nonhost" default),
+// silently dropping one system's gauge. It is called once, when the ProcessRegistry is created,
+// and should not be called again for the same key.
+func RegisterActorCountProvider(key string, address string, count func() int64) {
+	actorCountProvidersMu.Lock()
+	defer actorCountProvidersMu.Unlock()
+	actorCountProviders[key] = actorCountProvider{address: address, count: count}
+}
+
+// UnregisterActorCountProvider removes the provider registered under key by
+// RegisterActorCountProvider, called from ActorSystem.Shutdown.
+func UnregisterActorCountProvider(key string) {
+	actorCountProvidersMu.Lock()
+	defer actorCountProvidersMu.Unlock()
+	delete(actorCountProviders, key)
+}
+
+// labelsToString never mutates labels: it sorts a copy so concurrent callers sharing the same
+// backing array (e.g. CommonLabels reused across goroutines) don't race on the caller's slice.
 func labelsToString(labels []attribute.KeyValue) string {
-	sb := strings.Builder{}
-	sort.Slice(labels, func(i, j int) bool {
-		return labels[i].Key < labels[j].Key
+	sorted := make([]attribute.KeyValue, len(labels))
+	copy(sorted, labels)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
 	})
-	for _, label := range labels {
+
+	sb := strings.Builder{}
+	for _, label := range sorted {
 		sb.WriteString(string(label.Key))
 		sb.WriteRune('=')
 		sb.WriteString(label.Value.Emit())
@@ -0,0 +1,128 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// NewPrometheusMetrics builds a Metrics extension backed directly by a Prometheus
+// Registry, for services that want protoactor's built-in actor metrics without taking
+// an OpenTelemetry dependency. The gauges are served by a single prometheus.Collector
+// pulling from the same provider registries the OTel async gauges use (mailboxSizeRegistry,
+// stashSizeRegistry, childrenCountRegistry, actorCountProviders), so label semantics
+// from CommonLabels are identical between the two backends.
+//
+// reg is a concrete *prometheus.Registry rather than the prometheus.Registerer interface so
+// Handler can always gather back what was just registered: some Registerer implementations
+// (e.g. prometheus.WrapRegistererWith) don't also implement prometheus.Gatherer, which would
+// otherwise leave Handler silently returning nil.
+func NewPrometheusMetrics(reg *prometheus.Registry) *Metrics {
+	if reg == nil {
+		return &Metrics{}
+	}
+
+	recorder := &prometheusInstrumentRecorder{
+		restartCount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "protoactor_actor_restarts_total",
+			Help: "Number of actor restarts",
+		}, []string{"address", "actortype"}),
+		receiveDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "protoactor_actor_message_receive_duration_seconds",
+			Help: "Actor's Message Receive Duration",
+		}, []string{"address", "actortype", "messagetype"}),
+	}
+	reg.MustRegister(recorder.restartCount, recorder.receiveDuration)
+	reg.MustRegister(newActorGaugeCollector())
+
+	return &Metrics{
+		enabled:  true,
+		recorder: recorder,
+		registry: reg,
+	}
+}
+
+// Handler returns an http.Handler serving the registry passed to NewPrometheusMetrics, for
+// mounting at e.g. /metrics. It is nil when Metrics was built with NewMetrics instead, since
+// NewPrometheusMetrics is the only constructor that ever assigns m.registry.
+func (m *Metrics) Handler() http.Handler {
+	registry, ok := m.registry.(*prometheus.Registry)
+	if !ok {
+		return nil
+	}
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+type prometheusInstrumentRecorder struct {
+	restartCount    *prometheus.CounterVec
+	receiveDuration *prometheus.HistogramVec
+}
+
+func (r *prometheusInstrumentRecorder) RecordRestart(labels []attribute.KeyValue) {
+	r.restartCount.WithLabelValues(attrValue(labels, "address"), attrValue(labels, "actortype")).Inc()
+}
+
+func (r *prometheusInstrumentRecorder) RecordReceiveDuration(labels []attribute.KeyValue, seconds float64) {
+	r.receiveDuration.WithLabelValues(
+		attrValue(labels, "address"), attrValue(labels, "actortype"), attrValue(labels, "messagetype"),
+	).Observe(seconds)
+}
+
+func attrValue(labels []attribute.KeyValue, key string) string {
+	for _, l := range labels {
+		if string(l.Key) == key {
+			return l.Value.Emit()
+		}
+	}
+	return ""
+}
+
+var (
+	mailboxLengthDesc = prometheus.NewDesc("protoactor_actor_mailbox_length",
+		"Actor's Mailbox Length", []string{"address", "actortype"}, nil)
+	stashSizeDesc = prometheus.NewDesc("protoactor_actor_stash_size",
+		"Actor's Stash Size", []string{"address", "actortype"}, nil)
+	childrenCountDesc = prometheus.NewDesc("protoactor_actor_children_count",
+		"Actor's Children Count", []string{"address", "actortype"}, nil)
+	systemActorCountDesc = prometheus.NewDesc("protoactor_actor_system_actor_count",
+		"Number of actors currently registered with the ActorSystem", []string{"address"}, nil)
+)
+
+// actorGaugeCollector is the Prometheus counterpart to the OTel async-gauge callbacks
+// registered in NewMetrics: every scrape pulls the same provider registries, so both
+// backends see an identical view of mailbox/stash/children/actor counts.
+type actorGaugeCollector struct{}
+
+func newActorGaugeCollector() *actorGaugeCollector {
+	return &actorGaugeCollector{}
+}
+
+func (c *actorGaugeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mailboxLengthDesc
+	ch <- stashSizeDesc
+	ch <- childrenCountDesc
+	ch <- systemActorCountDesc
+}
+
+func (c *actorGaugeCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, lv := range snapshotMailboxSizes() {
+		ch <- prometheus.MustNewConstMetric(mailboxLengthDesc, prometheus.GaugeValue, float64(lv.value),
+			attrValue(lv.labels, "address"), attrValue(lv.labels, "actortype"))
+	}
+	for _, lv := range snapshotStashSizes() {
+		ch <- prometheus.MustNewConstMetric(stashSizeDesc, prometheus.GaugeValue, float64(lv.value),
+			attrValue(lv.labels, "address"), attrValue(lv.labels, "actortype"))
+	}
+	for _, lv := range snapshotChildrenCounts() {
+		ch <- prometheus.MustNewConstMetric(childrenCountDesc, prometheus.GaugeValue, float64(lv.value),
+			attrValue(lv.labels, "address"), attrValue(lv.labels, "actortype"))
+	}
+	for _, lv := range snapshotActorCounts() {
+		ch <- prometheus.MustNewConstMetric(systemActorCountDesc, prometheus.GaugeValue, float64(lv.value),
+			attrValue(lv.labels, "address"))
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestPrometheusMetricsCollectAndHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewPrometheusMetrics(reg)
+
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+	deregister := registerMailboxSizeProvider(func() (int, bool) { return 5, false }, labels)
+	defer deregister()
+
+	m.RecordRestart(&actorContext{system: &ActorSystem{address: "local"}, actor: receiveFunc(func(Context) {})})
+
+	handler := m.Handler()
+	if handler == nil {
+		t.Fatalf("expected Handler to return a non-nil http.Handler for a Metrics built with NewPrometheusMetrics")
+	}
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body, err := io.ReadAll(rec.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read handler response: %v", err)
+	}
+
+	if !strings.Contains(string(body), "protoactor_actor_mailbox_length") {
+		t.Fatalf("expected mailbox length gauge in scrape output, got:\n%s", body)
+	}
+	if !strings.Contains(string(body), "protoactor_actor_restarts_total") {
+		t.Fatalf("expected restart counter in scrape output, got:\n%s", body)
+	}
+}
+
+func TestPrometheusMetricsHandlerNilWithoutRegistry(t *testing.T) {
+	m := NewMetrics(nil)
+	if m.Handler() != nil {
+		t.Fatalf("expected Handler to be nil for a Metrics built with NewMetrics")
+	}
+}
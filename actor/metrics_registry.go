@@ -0,0 +1,160 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// registryShardCount controls how many independent locks a sizeProviderRegistry spreads its
+// label sets across, so concurrent scrapes (OTel callback vs. Prometheus Collect, or just many
+// scrapers) don't serialize on a single global lock.
+const registryShardCount = 32
+
+// registryHandle identifies a single registered invoker within a sizeProviderRegistry. Holding
+// on to it lets a caller deregister in O(1) instead of waiting for the scrape loop to notice
+// the invoker reports itself dead.
+type registryHandle uint64
+
+var nextRegistryHandle uint64
+
+func newRegistryHandle() registryHandle {
+	return registryHandle(atomic.AddUint64(&nextRegistryHandle, 1))
+}
+
+// sizeInvoker is the shape shared by mailboxSizeInvoker, stashSizeInvoker and
+// childrenCountInvoker: report a live value, or signal the invoker is stale so the registry can
+// drop it.
+type sizeInvoker func() (size int, dead bool)
+
+// sizeProviderRegistry is the scalable replacement for the old single global
+// map[string]*mailboxSizeProvider + container/list.List design: label sets are sharded by hash
+// so scrapes can run in parallel, and invokers live in a sync.Map keyed by registryHandle so
+// Deregister is O(1) instead of a linear list walk.
+type sizeProviderRegistry struct {
+	shards [registryShardCount]sizeProviderShard
+}
+
+type sizeProviderShard struct {
+	mu        sync.RWMutex
+	providers map[string]*sizeProviderEntry
+}
+
+type sizeProviderEntry struct {
+	labels   []attribute.KeyValue
+	invokers sync.Map // registryHandle -> sizeInvoker
+	live     int64    // atomic count of registered invokers; entry is dropped once this hits 0
+}
+
+func newSizeProviderRegistry() *sizeProviderRegistry {
+	r := &sizeProviderRegistry{}
+	for i := range r.shards {
+		r.shards[i].providers = make(map[string]*sizeProviderEntry)
+	}
+	return r
+}
+
+// register adds invoker under labels and returns a Deregister closure. The caller must invoke
+// it exactly once, from the PID's stop path, so the entry doesn't outlive the actor.
+func (r *sizeProviderRegistry) register(invoker sizeInvoker, labels []attribute.KeyValue) func() {
+	key := labelsToString(labels)
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	shard := &r.shards[h.Sum32()%registryShardCount]
+
+	shard.mu.Lock()
+	entry, ok := shard.providers[key]
+	if !ok {
+		entry = &sizeProviderEntry{labels: labels}
+		shard.providers[key] = entry
+	}
+	handle := newRegistryHandle()
+	entry.invokers.Store(handle, invoker)
+	atomic.AddInt64(&entry.live, 1)
+	shard.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			shard.removeHandle(key, entry, handle)
+		})
+	}
+}
+
+// removeHandle drops handle from entry, and entry from the shard's map once its last invoker
+// is gone. It's shared by the Deregister closure returned from register and by snapshot's
+// dead-invoker sweep below, so however a handle is removed, the shard map and the live count
+// stay in lockstep: entry.invokers.LoadAndDelete makes the removal itself idempotent (a second
+// caller racing on the same handle finds it already gone and does nothing). The live decrement
+// and the map delete happen under the same shard lock that register uses to store a new
+// invoker and bump live, so a register racing a concurrent Deregister of the entry's last other
+// invoker can never land its invoker on an entry that's about to be (or was just) evicted from
+// shard.providers.
+func (shard *sizeProviderShard) removeHandle(key string, entry *sizeProviderEntry, handle registryHandle) {
+	if _, loaded := entry.invokers.LoadAndDelete(handle); !loaded {
+		return
+	}
+	shard.mu.Lock()
+	if atomic.AddInt64(&entry.live, -1) == 0 && shard.providers[key] == entry {
+		delete(shard.providers, key)
+	}
+	shard.mu.Unlock()
+}
+
+// snapshot sums every live invoker per label set, sweeping any invoker that reports itself
+// dead along the way. Shards are scraped concurrently since each carries its own lock. A label
+// set whose last invoker dies during this same scrape is omitted entirely rather than reported
+// as a trailing zero, since by the time the loop below checks live the entry is already gone
+// from shard.providers.
+func (r *sizeProviderRegistry) snapshot() []labeledInt64 {
+	var wg sync.WaitGroup
+	perShard := make([][]labeledInt64, registryShardCount)
+
+	for i := range r.shards {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			shard := &r.shards[i]
+
+			shard.mu.RLock()
+			entries := make(map[string]*sizeProviderEntry, len(shard.providers))
+			for key, entry := range shard.providers {
+				entries[key] = entry
+			}
+			shard.mu.RUnlock()
+
+			values := make([]labeledInt64, 0, len(entries))
+			for key, entry := range entries {
+				var count int64
+				entry.invokers.Range(func(k, value interface{}) bool {
+					if c, dead := value.(sizeInvoker)(); dead {
+						shard.removeHandle(key, entry, k.(registryHandle))
+					} else {
+						count += int64(c)
+					}
+					return true
+				})
+				if atomic.LoadInt64(&entry.live) == 0 {
+					continue
+				}
+				values = append(values, labeledInt64{labels: entry.labels, value: count})
+			}
+			perShard[i] = values
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, values := range perShard {
+		total += len(values)
+	}
+	result := make([]labeledInt64, 0, total)
+	for _, values := range perShard {
+		result = append(result, values...)
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestSizeProviderRegistrySnapshotSweepsDeadInvokers(t *testing.T) {
+	r := newSizeProviderRegistry()
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+
+	r.register(func() (int, bool) { return 0, true }, labels)
+
+	values := r.snapshot()
+	if len(values) != 0 {
+		t.Fatalf("expected a dead invoker to be swept with no observations left, got %v", values)
+	}
+
+	for i := range r.shards {
+		shard := &r.shards[i]
+		if len(shard.providers) != 0 {
+			t.Fatalf("shard %d still holds an entry after its only invoker was swept as dead", i)
+		}
+	}
+}
+
+func TestSizeProviderRegistryDeregisterAfterSweepIsNoop(t *testing.T) {
+	r := newSizeProviderRegistry()
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+
+	deregister := r.register(func() (int, bool) { return 0, true }, labels)
+
+	// The sweep during snapshot should already have removed the invoker and its entry.
+	r.snapshot()
+
+	// A late Deregister call for the same handle (e.g. the PID's stop path running after a
+	// scrape already noticed it was dead) must be a no-op, not push live negative and orphan
+	// the entry forever.
+	deregister()
+
+	for i := range r.shards {
+		shard := &r.shards[i]
+		if len(shard.providers) != 0 {
+			t.Fatalf("shard %d leaked an entry after redundant Deregister, providers=%v", i, shard.providers)
+		}
+	}
+}
+
+func TestSizeProviderRegistryRegisterSnapshotDeregister(t *testing.T) {
+	r := newSizeProviderRegistry()
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+
+	deregisterA := r.register(func() (int, bool) { return 3, false }, labels)
+	deregisterB := r.register(func() (int, bool) { return 4, false }, labels)
+
+	values := r.snapshot()
+	if len(values) != 1 || values[0].value != 7 {
+		t.Fatalf("expected a single combined observation of 7, got %v", values)
+	}
+
+	deregisterA()
+	values = r.snapshot()
+	if len(values) != 1 || values[0].value != 4 {
+		t.Fatalf("expected the remaining invoker's value of 4 after deregistering the first, got %v", values)
+	}
+
+	deregisterB()
+	values = r.snapshot()
+	if len(values) != 0 {
+		t.Fatalf("expected no observations once every invoker is deregistered, got %v", values)
+	}
+	for i := range r.shards {
+		shard := &r.shards[i]
+		if len(shard.providers) != 0 {
+			t.Fatalf("shard %d leaked an entry after all invokers were deregistered", i)
+		}
+	}
+}
+
+// TestSizeProviderRegistryRegisterRacingLastDeregisterIsNotLost targets the interleaving where
+// a register() for a new invoker on a label set races a Deregister() of that same label set's
+// only other invoker: if the new invoker's Store/live-increment could ever land after
+// removeHandle had already decided live==0 and deleted the shard entry, the new invoker would
+// be stored on an orphaned entry and silently never scraped again (snapshot would report 0
+// forever, not a crash or a map leak). Looping gives a regression many chances to hit the
+// interleaving if register and removeHandle ever stop sharing the same critical section.
+func TestSizeProviderRegistryRegisterRacingLastDeregisterIsNotLost(t *testing.T) {
+	r := newSizeProviderRegistry()
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+
+	for i := 0; i < 2000; i++ {
+		deregisterA := r.register(func() (int, bool) { return 1, false }, labels)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		var deregisterB func()
+		go func() {
+			defer wg.Done()
+			deregisterA()
+		}()
+		go func() {
+			defer wg.Done()
+			deregisterB = r.register(func() (int, bool) { return 2, false }, labels)
+		}()
+		wg.Wait()
+
+		values := r.snapshot()
+		if len(values) != 1 || values[0].value != 2 {
+			t.Fatalf("iteration %d: expected the surviving invoker's value of 2 after the racing deregister, got %v", i, values)
+		}
+
+		deregisterB()
+	}
+}
+
+func TestSizeProviderRegistryConcurrentRegisterDeregister(t *testing.T) {
+	r := newSizeProviderRegistry()
+	labels := []attribute.KeyValue{attribute.String("address", "local"), attribute.String("actortype", "T")}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deregister := r.register(func() (int, bool) { return 1, false }, labels)
+			r.snapshot()
+			deregister()
+		}()
+	}
+	wg.Wait()
+
+	values := r.snapshot()
+	if len(values) != 0 {
+		t.Fatalf("expected no observations once every concurrent registration unwound, got %v", values)
+	}
+	for i := range r.shards {
+		shard := &r.shards[i]
+		if len(shard.providers) != 0 {
+			t.Fatalf("shard %d leaked an entry after concurrent register/deregister", i)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+// PID identifies a single actor: its system's address plus a locally-unique id.
+type PID struct {
+	Address string
+	Id      string
+}
+
+func (p *PID) String() string {
+	if p == nil {
+		return ""
+	}
+	return p.Address + "/" + p.Id
+}
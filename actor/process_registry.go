@@ -0,0 +1,48 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import "sync"
+
+// ProcessRegistry tracks every actor currently spawned on an ActorSystem. It backs
+// protoactor_actor_system_actor_count via ActorCount, registered through
+// RegisterActorCountProvider by NewActorSystem.
+type ProcessRegistry struct {
+	system *ActorSystem
+
+	mu     sync.RWMutex
+	actors map[string]*actorContext
+}
+
+func newProcessRegistry(system *ActorSystem) *ProcessRegistry {
+	return &ProcessRegistry{
+		system: system,
+		actors: make(map[string]*actorContext),
+	}
+}
+
+func (r *ProcessRegistry) add(ctx *actorContext) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actors[ctx.self.Id] = ctx
+}
+
+func (r *ProcessRegistry) remove(pid *PID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.actors, pid.Id)
+}
+
+func (r *ProcessRegistry) get(pid *PID) *actorContext {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actors[pid.Id]
+}
+
+// ActorCount returns the number of actors currently registered, observed asynchronously by
+// protoactor_actor_system_actor_count.
+func (r *ProcessRegistry) ActorCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.actors)
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Producer creates a fresh Actor instance. It's called once when an actor is spawned, and
+// again by the supervisor every time that actor is restarted.
+type Producer func() Actor
+
+// Props describes how to spawn an actor. It's intentionally small: just enough for Spawn to
+// create the behavior and wire it into the Metrics registries.
+type Props struct {
+	producer           Producer
+	supervisorStrategy SupervisorStrategy
+}
+
+func PropsFromProducer(producer Producer) *Props {
+	return &Props{producer: producer}
+}
+
+// WithSupervisor overrides the default restart-on-failure strategy.
+func (props *Props) WithSupervisor(strategy SupervisorStrategy) *Props {
+	props.supervisorStrategy = strategy
+	return props
+}
+
+var pidSequence uint64
+
+func nextPIDId() string {
+	return fmt.Sprintf("$%d", atomic.AddUint64(&pidSequence, 1))
+}
+
+// Spawn creates a new actor under system from props, starting it and registering it with the
+// ProcessRegistry and its protoactor_actor_children_count gauge.
+func (props *Props) Spawn(system *ActorSystem, parent *PID) *PID {
+	self := &PID{Address: system.Address(), Id: nextPIDId()}
+	ctx := newActorContext(system, props, self, parent)
+	ctx.start()
+
+	if parent != nil {
+		if parentCtx := system.ProcessRegistry.get(parent); parentCtx != nil {
+			parentCtx.addChild(self)
+		}
+	}
+
+	return self
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+// Directive tells the supervisor what to do about a failed actor.
+type Directive int
+
+const (
+	DirectiveResume Directive = iota
+	DirectiveRestart
+	DirectiveStop
+)
+
+// SupervisorStrategy decides a Directive for a child that failed with reason. Props defaults
+// to restartDirectiveStrategy{} when none is set via Props.WithSupervisor.
+type SupervisorStrategy interface {
+	HandleFailure(system *ActorSystem, child Context, reason interface{}) Directive
+}
+
+// restartDirectiveStrategy is the default SupervisorStrategy: always restart.
+type restartDirectiveStrategy struct{}
+
+func (restartDirectiveStrategy) HandleFailure(*ActorSystem, Context, interface{}) Directive {
+	return DirectiveRestart
+}
+
+// handleFailure is called by invokeUserMessage when Actor.Receive panics. It asks the actor's
+// SupervisorStrategy what to do and applies the resulting Directive.
+func (ctx *actorContext) handleFailure(reason interface{}) {
+	strategy := ctx.props.supervisorStrategy
+	if strategy == nil {
+		strategy = restartDirectiveStrategy{}
+	}
+
+	switch strategy.HandleFailure(ctx.system, ctx, reason) {
+	case DirectiveRestart:
+		ctx.restart()
+	case DirectiveStop:
+		ctx.stop()
+	case DirectiveResume:
+		// leave the actor's state untouched and keep going
+	}
+}
+
+// restart records the restart against protoactor_actor_restarts_total and re-creates the
+// actor's behavior via its Props.producer, exactly as the real PID is restarted in place.
+func (ctx *actorContext) restart() {
+	ctx.system.Metrics().RecordRestart(ctx)
+	ctx.actor = ctx.props.producer()
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"context"
+
+	"github.com/asynkron/protoactor-go/extensions"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracingExtensionId = extensions.NextExtensionID()
+
+const tracingLibName = "github.com/asynkron/protoactor-go"
+
+// Tracing is the distributed-tracing counterpart to Metrics: an extensions.Extension that,
+// when enabled with a trace.TracerProvider, starts a span around every message send and a
+// linked child span around the matching receive, so a single request can be followed across
+// actors and across the wire.
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	enabled    bool
+}
+
+var _ extensions.Extension = &Tracing{}
+
+func (t *Tracing) Enabled() bool {
+	return t.enabled
+}
+
+func (t *Tracing) ExtensionID() extensions.ExtensionID {
+	return tracingExtensionId
+}
+
+// NewTracing builds a Tracing extension from provider. A nil provider yields a disabled,
+// no-op Tracing so callers don't need to nil-check before use, mirroring NewMetrics.
+func NewTracing(provider trace.TracerProvider) *Tracing {
+	if provider == nil {
+		return &Tracing{
+			tracer:     trace.NewNoopTracerProvider().Tracer(tracingLibName),
+			propagator: propagation.TraceContext{},
+		}
+	}
+
+	return &Tracing{
+		tracer:     provider.Tracer(tracingLibName),
+		propagator: propagation.TraceContext{},
+		enabled:    true,
+	}
+}
+
+// TracingHeaders carries the W3C traceparent/tracestate values across the wire so a remote
+// delivery can continue the sender's trace. Callers attach it to MessageHeaders under
+// TracingHeadersKey before handing the envelope to remote, and read it back on arrival.
+type TracingHeaders map[string]string
+
+// TracingHeadersKey is the MessageHeaders key under which InjectHeaders' output should be
+// stored so the receiving node can find it and call ExtractHeaders.
+const TracingHeadersKey = "proto.tracing"
+
+// StartSendSpan starts a span named "send <messageType>" tagged with the sending actor's
+// address/type/pid; WithTracingSenderMiddleware calls this around Context.Send/Request before
+// the message is handed to the target's mailbox (or to remote). The returned TracingHeaders
+// should be attached to the outgoing MessageHeaders so StartReceiveSpan can link to this span.
+func (t *Tracing) StartSendSpan(goCtx context.Context, ctx Context, messageType string) (context.Context, trace.Span, TracingHeaders) {
+	goCtx, span := t.tracer.Start(goCtx, "send "+messageType,
+		trace.WithAttributes(t.spanAttributes(ctx, messageType)...))
+	return goCtx, span, t.InjectHeaders(goCtx)
+}
+
+// StartReceiveSpan starts a span named "receive <messageType>", continuing the trace carried
+// in headers if present. Called from the context dispatch path right before Actor.Receive.
+func (t *Tracing) StartReceiveSpan(goCtx context.Context, ctx Context, messageType string, headers TracingHeaders) (context.Context, trace.Span) {
+	goCtx = t.ExtractHeaders(goCtx, headers)
+	goCtx, span := t.tracer.Start(goCtx, "receive "+messageType,
+		trace.WithAttributes(t.spanAttributes(ctx, messageType)...))
+	return goCtx, span
+}
+
+func (t *Tracing) spanAttributes(ctx Context, messageType string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("actor.address", ctx.ActorSystem().Address()),
+		attribute.String("actor.type", actorTypeName(ctx)),
+		attribute.String("message.type", messageType),
+	}
+	if self := ctx.Self(); self != nil {
+		attrs = append(attrs, attribute.String("actor.pid", self.String()))
+	}
+	return attrs
+}
+
+// InjectHeaders encodes the current span from goCtx as W3C traceparent/tracestate values.
+func (t *Tracing) InjectHeaders(goCtx context.Context) TracingHeaders {
+	headers := make(TracingHeaders)
+	t.propagator.Inject(goCtx, propagation.MapCarrier(headers))
+	return headers
+}
+
+// ExtractHeaders restores the span context carried in headers, if any, onto goCtx. A nil or
+// empty headers is a no-op so local, same-process sends don't need a branch.
+func (t *Tracing) ExtractHeaders(goCtx context.Context, headers TracingHeaders) context.Context {
+	if len(headers) == 0 {
+		return goCtx
+	}
+	return t.propagator.Extract(goCtx, propagation.MapCarrier(headers))
+}
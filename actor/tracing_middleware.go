@@ -0,0 +1,62 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReceiverFunc is the shape of the context dispatch path's message handling: baseReceive itself
+// (plain Actor.Receive), or a user ReceiverMiddleware wrapping it. invokeUserMessage builds this
+// chain fresh for every message via ActorSystem.wrapReceiver.
+type ReceiverFunc func(ctx Context)
+
+// ReceiverMiddleware wraps a ReceiverFunc with additional behavior, composed around
+// invokeUserMessage the same way Sender/Receiver middlewares are composed everywhere else in
+// protoactor-go.
+type ReceiverMiddleware func(next ReceiverFunc) ReceiverFunc
+
+// SenderFunc is the shape of a send: baseSend itself (hand message straight to target), or a
+// user SenderMiddleware wrapping it. Context.Send builds this chain fresh for every send via
+// ActorSystem.wrapSender. headers is nil unless a middleware earlier in the chain (e.g.
+// WithTracingSenderMiddleware) injected TracingHeaders for the receive side to pick up.
+type SenderFunc func(ctx Context, target *PID, message interface{}, headers TracingHeaders)
+
+// SenderMiddleware wraps a SenderFunc with additional behavior.
+type SenderMiddleware func(next SenderFunc) SenderFunc
+
+// WithTracingReceiverMiddleware returns a ReceiverMiddleware that brackets next with a
+// "receive <MessageType>" span from tracing, continuing the sender's trace via the
+// TracingHeaders invokeUserMessage stashed on ctx (nil if the sender had no Tracing middleware
+// of its own, e.g. Tracing was enabled on the receiving system only). This lets users fold
+// tracing into their own middleware chain instead of calling Tracing.StartReceiveSpan by hand.
+func WithTracingReceiverMiddleware(tracing *Tracing) ReceiverMiddleware {
+	return func(next ReceiverFunc) ReceiverFunc {
+		return func(ctx Context) {
+			messageType := fmt.Sprintf("%T", ctx.Message())
+			headers := ctx.(*actorContext).headers
+			_, span := tracing.StartReceiveSpan(context.Background(), ctx, messageType, headers)
+			defer span.End()
+
+			next(ctx)
+		}
+	}
+}
+
+// WithTracingSenderMiddleware returns a SenderMiddleware that brackets next with a
+// "send <MessageType>" span from tracing, and passes the injected TracingHeaders down the
+// SenderFunc chain so baseSend can stash them on the target's ctx for
+// WithTracingReceiverMiddleware to pick back up. This lets users fold tracing into their own
+// middleware chain instead of calling Tracing.StartSendSpan by hand.
+func WithTracingSenderMiddleware(tracing *Tracing) SenderMiddleware {
+	return func(next SenderFunc) SenderFunc {
+		return func(ctx Context, target *PID, message interface{}, _ TracingHeaders) {
+			messageType := fmt.Sprintf("%T", message)
+			_, span, headers := tracing.StartSendSpan(context.Background(), ctx, messageType)
+			defer span.End()
+
+			next(ctx, target, message, headers)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+package actor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanTraceCapture is a minimal sdktrace.SpanProcessor that records each ended span's trace ID
+// by name, so tests can check that two spans produced by separate Tracing calls (send vs.
+// receive) ended up in the same trace instead of each starting its own root span.
+type spanTraceCapture struct {
+	mu     sync.Mutex
+	byName map[string]trace.TraceID
+}
+
+func newSpanTraceCapture() *spanTraceCapture {
+	return &spanTraceCapture{byName: make(map[string]trace.TraceID)}
+}
+
+func (c *spanTraceCapture) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (c *spanTraceCapture) OnEnd(s sdktrace.ReadOnlySpan) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byName[s.Name()] = s.SpanContext().TraceID()
+}
+
+func (c *spanTraceCapture) Shutdown(context.Context) error   { return nil }
+func (c *spanTraceCapture) ForceFlush(context.Context) error { return nil }
+
+func (c *spanTraceCapture) traceID(name string) (trace.TraceID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.byName[name]
+	return id, ok
+}
+
+func TestTracingHeadersRoundTrip(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	tracing := NewTracing(provider)
+	ctx := &actorContext{
+		system: &ActorSystem{address: "test"},
+		actor:  receiveFunc(func(Context) {}),
+		self:   &PID{Address: "test", Id: "$1"},
+	}
+
+	sendCtx, sendSpan, headers := tracing.StartSendSpan(context.Background(), ctx, "TestMessage")
+	sendSpan.End()
+
+	if len(headers) == 0 {
+		t.Fatalf("expected InjectHeaders to populate at least the traceparent header")
+	}
+
+	receiveCtx, receiveSpan := tracing.StartReceiveSpan(context.Background(), ctx, "TestMessage", headers)
+	defer receiveSpan.End()
+
+	sendTraceID := trace.SpanContextFromContext(sendCtx).TraceID()
+	receiveTraceID := trace.SpanContextFromContext(receiveCtx).TraceID()
+	if sendTraceID != receiveTraceID {
+		t.Fatalf("expected the receive span to continue the send span's trace, got %s vs %s", sendTraceID, receiveTraceID)
+	}
+}
+
+// TestContextSendContinuesTraceIntoReceive drives a real ctx.Send through
+// WithTracingSenderMiddleware/WithTracingReceiverMiddleware (not StartSendSpan/StartReceiveSpan
+// called directly) and asserts the "send ..." and "receive ..." spans share a trace ID, i.e. the
+// TracingHeaders StartSendSpan injects actually make it to the receiving actor's
+// StartReceiveSpan call instead of each side starting its own disconnected root span.
+func TestContextSendContinuesTraceIntoReceive(t *testing.T) {
+	capture := newSpanTraceCapture()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(capture))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	system := NewActorSystem(NewActorSystemConfig(WithTracing(provider)))
+	defer system.Shutdown()
+
+	received := make(chan struct{})
+	targetPID := PropsFromProducer(func() Actor {
+		return receiveFunc(func(Context) { close(received) })
+	}).Spawn(system, nil)
+
+	senderPID := PropsFromProducer(func() Actor { return receiveFunc(func(Context) {}) }).Spawn(system, nil)
+	senderCtx := system.ProcessRegistry.get(senderPID)
+
+	senderCtx.Send(targetPID, "TestMessage")
+	<-received
+
+	sendTraceID, ok := capture.traceID("send string")
+	if !ok {
+		t.Fatalf("expected a \"send string\" span to have been recorded")
+	}
+	receiveTraceID, ok := capture.traceID("receive string")
+	if !ok {
+		t.Fatalf("expected a \"receive string\" span to have been recorded")
+	}
+	if sendTraceID != receiveTraceID {
+		t.Fatalf("expected ctx.Send's receive span to continue the send span's trace, got %s vs %s", sendTraceID, receiveTraceID)
+	}
+}
+
+func TestExtractHeadersNoopOnEmptyHeaders(t *testing.T) {
+	tracing := NewTracing(nil)
+	goCtx := context.Background()
+
+	if got := tracing.ExtractHeaders(goCtx, nil); got != goCtx {
+		t.Fatalf("expected ExtractHeaders to return goCtx unchanged for empty headers")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (C) 2017 - 2022 Asynkron.se <http://www.asynkron.se>
+
+// Package metrics is the OpenTelemetry instrument set shared by every protoactor-go subsystem
+// that exposes metrics (actor today; remote/cluster mirror the same shape when they add their
+// own). ProtoMetrics owns the MeterProvider and hands each subsystem its own named Instruments,
+// so two subsystems never fight over the same meter.
+package metrics
+
+import (
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument/asyncint64"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+)
+
+// LibName is the meter name every subsystem's global.Meter(LibName) call should use, so all of
+// protoactor-go's instruments are registered against the same OTel Meter.
+const LibName = "github.com/asynkron/protoactor-go"
+
+// InternalActorMetrics is the key the actor package's instrument set is registered under.
+const InternalActorMetrics = "internal.actor"
+
+// ProtoMetrics owns the instrument sets for every protoactor-go subsystem that has one. It is
+// built once per MeterProvider and shared across subsystems via Get.
+type ProtoMetrics struct {
+	provider    metric.MeterProvider
+	instruments map[string]*Instruments
+}
+
+// NewProtoMetrics builds a ProtoMetrics backed by provider, with an empty Instruments already
+// registered under InternalActorMetrics for the actor package to fill in via Instruments().
+func NewProtoMetrics(provider metric.MeterProvider) *ProtoMetrics {
+	return &ProtoMetrics{
+		provider: provider,
+		instruments: map[string]*Instruments{
+			InternalActorMetrics: {},
+		},
+	}
+}
+
+// Get returns the named instrument set, or nil if nothing registered one under key.
+func (p *ProtoMetrics) Get(key string) *Instruments {
+	return p.instruments[key]
+}
+
+// Instruments returns the actor package's instrument set, the one every Prepare*Gauge/Counter/
+// Histogram method on actor.Metrics fills in.
+func (p *ProtoMetrics) Instruments() *Instruments {
+	return p.instruments[InternalActorMetrics]
+}
+
+// Instruments holds the concrete OTel instrument handles for a single subsystem. Every field
+// starts out nil and is assigned exactly once, by the matching SetXxx method, right after the
+// instrument is created.
+type Instruments struct {
+	ActorMailboxLength          asyncint64.Gauge
+	ActorStashSize              asyncint64.Gauge
+	ActorChildrenCount          asyncint64.Gauge
+	ActorSystemActorCount       asyncint64.Gauge
+	ActorRestartCount           syncint64.Counter
+	ActorMessageReceiveDuration syncfloat64.Histogram
+}
+
+func (i *Instruments) SetActorMailboxLengthGauge(gauge asyncint64.Gauge) {
+	i.ActorMailboxLength = gauge
+}
+
+func (i *Instruments) SetActorStashSizeGauge(gauge asyncint64.Gauge) {
+	i.ActorStashSize = gauge
+}
+
+func (i *Instruments) SetActorChildrenCountGauge(gauge asyncint64.Gauge) {
+	i.ActorChildrenCount = gauge
+}
+
+func (i *Instruments) SetActorSystemActorCountGauge(gauge asyncint64.Gauge) {
+	i.ActorSystemActorCount = gauge
+}
+
+func (i *Instruments) SetActorRestartCountCounter(counter syncint64.Counter) {
+	i.ActorRestartCount = counter
+}
+
+func (i *Instruments) SetActorMessageReceiveDurationHistogram(histogram syncfloat64.Histogram) {
+	i.ActorMessageReceiveDuration = histogram
+}